@@ -12,6 +12,7 @@ import (
 
 	c "github.com/theQRL/go-qrl/config"
 	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/core/event"
 	"github.com/theQRL/go-qrl/core/metadata"
 	"github.com/theQRL/go-qrl/core/pool"
 	"github.com/theQRL/go-qrl/core/transactions"
@@ -39,6 +40,12 @@ type Chain struct {
 	lastBlock *block.Block
 	currentDifficulty []byte
 
+	syncMode SyncMode
+	pivotHeaderHash []byte
+	headerChainHeight uint64
+
+	chainFeed    event.Feed
+	newBlockFeed event.Feed
 }
 
 func CreateChain(log *log.Logger, config *c.Config) (*Chain, error) {
@@ -150,6 +157,31 @@ func (c *Chain) Load(genesisBlock *block.Block) error {
 	return nil
 }
 
+// totalDifficulty returns the total difficulty accumulated up to
+// headerHash, reading it from its own td-<num>-<hash> entry so fork
+// comparisons in addBlock don't have to parse BlockMetaData's goqryptonight
+// UInt256 encoding on every call. Blocks written before this index existed
+// fall back to the old BlockMetaData-based parse and backfill the td entry
+// so the next lookup is cheap. This is a read/cache path only - it must
+// never touch the canonical num->hash mapping, since it's called on
+// contending blocks before fork choice has decided anything.
+func (c *Chain) totalDifficulty(headerHash []byte, blockNumber uint64) *big.Int {
+	if td, err := c.state.GetTd(headerHash, blockNumber); err == nil {
+		return td
+	}
+
+	blockMetadata, err := c.state.GetBlockMetadata(headerHash)
+	if err != nil {
+		return big.NewInt(0)
+	}
+
+	td := big.NewInt(0)
+	td.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(blockMetadata.TotalDifficulty())), 10)
+	c.state.WriteTd(headerHash, blockNumber, td, nil)
+
+	return td
+}
+
 func (c *Chain) addBlock(block *block.Block, batch *leveldb.Batch) (bool, bool) {
 	blockSizeLimit, err := c.state.GetBlockSizeLimit(block)
 
@@ -158,7 +190,7 @@ func (c *Chain) addBlock(block *block.Block, batch *leveldb.Batch) (bool, bool)
 		return false, false
 	}
 
-	if reflect.DeepEqual(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
+	if c.syncMode == FullSync && reflect.DeepEqual(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
 		if !c.applyBlock(block, batch) {
 			return false, false
 		}
@@ -170,13 +202,8 @@ func (c *Chain) addBlock(block *block.Block, batch *leveldb.Batch) (bool, bool)
 		return false, false
 	}
 
-	lastBlockMetadata, err := c.state.GetBlockMetadata(c.lastBlock.HeaderHash())
-	newBlockMetadata, err := c.state.GetBlockMetadata(block.HeaderHash())
-
-	lastBlockDifficulty := big.NewInt(0)
-	lastBlockDifficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(lastBlockMetadata.TotalDifficulty())), 10)
-	newBlockDifficulty := big.NewInt(0)
-	newBlockDifficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(newBlockMetadata.TotalDifficulty())), 10)
+	lastBlockDifficulty := c.totalDifficulty(c.lastBlock.HeaderHash(), c.lastBlock.BlockNumber())
+	newBlockDifficulty := c.totalDifficulty(block.HeaderHash(), block.BlockNumber())
 
 	if newBlockDifficulty.Cmp(lastBlockDifficulty) == 1 {
 		if !reflect.DeepEqual(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
@@ -196,12 +223,17 @@ func (c *Chain) addBlock(block *block.Block, batch *leveldb.Batch) (bool, bool)
 	return true, false
 }
 
+// AddBlock is deliberately not written with `defer c.lock.Unlock()`: the
+// chainFeed.Send below blocks until every subscriber has received the event
+// (see Feed.Send), and sending while still holding c.lock would let one slow
+// subscriber stall every other chain operation. The lock must be released
+// before the fan-out.
 func (c *Chain) AddBlock(block *block.Block) bool {
 	c.lock.Lock()
-	defer c.lock.Unlock()
 
-	if block.BlockNumber() < c.Height() - c.config.Dev.ReorgLimit {
+	if c.syncMode == FullSync && block.BlockNumber() < c.Height() - c.config.Dev.ReorgLimit {
 		c.log.Debug("Skipping block #%s as beyond re-org limit", block.BlockNumber())
+		c.lock.Unlock()
 		return false
 	}
 
@@ -209,6 +241,7 @@ func (c *Chain) AddBlock(block *block.Block) bool {
 
 	if err == nil {
 		c.log.Debug("Skipping block #%s is duplicate block", block.BlockNumber())
+		c.lock.Unlock()
 		return false
 	}
 
@@ -219,11 +252,13 @@ func (c *Chain) AddBlock(block *block.Block) bool {
 			c.state.WriteBatch(batch)
 		}
 		c.log.Info("Added Block #%s %s", block.BlockNumber(), string(block.HeaderHash()))
+		c.lock.Unlock()
+		c.chainFeed.Send(ChainEvent{Block: block})
 		return true
 	}
 
+	c.lock.Unlock()
 	return false
-
 }
 
 func (c *Chain) applyBlock(block *block.Block, batch *leveldb.Batch) bool {
@@ -0,0 +1,44 @@
+package chain
+
+import (
+	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/core/event"
+)
+
+// ChainEvent is published once a block has been fully validated and
+// committed to the chain, whether it arrived from a peer or was produced
+// locally.
+type ChainEvent struct {
+	Block *block.Block
+}
+
+// NewMinedBlockEvent is published as soon as the local miner has produced a
+// valid block, before AddBlock runs full validation and writes it to
+// LevelDB. Subscribers (the P2P layer) can start gossiping the block
+// immediately instead of waiting for local application to finish, since the
+// miner already guarantees it is valid.
+type NewMinedBlockEvent struct {
+	Block *block.Block
+}
+
+// SubscribeChainEvents registers ch to receive every block this chain
+// commits, regardless of origin.
+func (c *Chain) SubscribeChainEvents(ch chan<- ChainEvent) event.Subscription {
+	return c.chainFeed.Subscribe(ch)
+}
+
+// SubscribeNewBlock registers ch to receive locally-mined blocks as soon as
+// they're produced, ahead of local validation/application.
+func (c *Chain) SubscribeNewBlock(ch chan<- NewMinedBlockEvent) event.Subscription {
+	return c.newBlockFeed.Subscribe(ch)
+}
+
+// AddMinedBlock is the entry point for blocks produced by this node's own
+// miner. Unlike AddBlock, it publishes NewMinedBlockEvent to subscribers
+// before running addBlock's validation and LevelDB write, so propagation to
+// peers can happen in parallel with local application instead of after it.
+func (c *Chain) AddMinedBlock(b *block.Block) bool {
+	c.newBlockFeed.Send(NewMinedBlockEvent{Block: b})
+
+	return c.AddBlock(b)
+}
@@ -0,0 +1,177 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/core/metadata"
+	"github.com/theQRL/go-qrl/generated"
+	"github.com/theQRL/go-qrl/misc"
+	"github.com/theQRL/go-qrl/pow"
+	"github.com/theQRL/qryptonight/goqryptonight"
+)
+
+// SyncMode selects how AddBlock ingests new blocks. FullSync replays every
+// block's state transitions as it arrives; FastSync only validates and
+// stores headers until a pivot AddressState snapshot has been downloaded,
+// after which it falls back to FullSync from pivot+1 onward.
+type SyncMode int
+
+const (
+	FullSync SyncMode = iota
+	FastSync
+)
+
+// PivotWindow is how many blocks behind the network head the pivot header is
+// picked, mirroring eth/63 fast sync.
+const PivotWindow = 64
+
+// SetSyncMode switches the chain between full and fast sync. It must be
+// called before any blocks are processed via AddBlock/InsertHeaderChain in
+// the new mode.
+func (c *Chain) SetSyncMode(mode SyncMode) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.syncMode = mode
+}
+
+func (c *Chain) SyncMode() SyncMode {
+	return c.syncMode
+}
+
+// InsertHeaderChain validates a contiguous run of headers and stores only
+// the BlockMetaData/BlockNumberMapping needed to extend the header chain,
+// without touching AddressState. It is the header-only counterpart of
+// AddBlock used while FastSyncing. On the first invalid header it stops and
+// returns its index; the headers before it have already been stored, each in
+// its own WriteBatch. headerChainHeight is advanced as each
+// header lands - c.lastBlock/Height() stay pinned at the last fully-applied
+// block throughout fast sync, so MaybeSelectPivot needs this separate tip to
+// know how far the header chain itself has actually reached.
+func (c *Chain) InsertHeaderChain(headers []*block.BlockHeader) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	dt := pow.DifficultyTracker{}
+
+	for i, header := range headers {
+		lastMetaData, err := c.state.GetBlockMetadata(header.PrevHeaderHash())
+		if err != nil {
+			return i, err
+		}
+
+		currentDifficulty, err := dt.Get(uint64(c.config.Dev.MiningSetpointBlocktime), lastMetaData.BlockDifficulty())
+		if err != nil {
+			return i, err
+		}
+
+		if !pow.PoWValidator(header, currentDifficulty) {
+			return i, errors.New("PoW validation failed for header #" + strconv.FormatUint(header.BlockNumber(), 10))
+		}
+
+		lastTotalDifficulty := big.NewInt(0)
+		lastTotalDifficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(lastMetaData.TotalDifficulty())), 10)
+		newTotalDifficulty := big.NewInt(0)
+		newTotalDifficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(currentDifficulty)), 10)
+		newTotalDifficulty.Add(newTotalDifficulty, lastTotalDifficulty)
+
+		batch := c.state.GetBatch()
+
+		blockNumberMapping := &generated.BlockNumberMapping{Headerhash: header.HeaderHash(),
+			PrevHeaderhash: header.PrevHeaderHash()}
+		c.state.PutBlockNumberMapping(header.BlockNumber(), blockNumberMapping, batch)
+
+		blockMetaData := metadata.CreateBlockMetadata(currentDifficulty,
+			misc.UCharVectorToBytes(goqryptonight.StringToUInt256(newTotalDifficulty.String())), nil)
+		c.state.PutBlockMetaData(header.HeaderHash(), blockMetaData, batch)
+
+		c.state.WriteBatch(batch)
+
+		c.headerChainHeight = header.BlockNumber()
+	}
+
+	return len(headers), nil
+}
+
+// MaybeSelectPivot picks the pivot header once the header chain is within
+// PivotWindow blocks of headHeight, and kicks off the AddressState snapshot
+// download for it. The pivot commit is atomic: the chain only flips back to
+// FullSync, and resumes applying blocks from pivot+1, once the full snapshot
+// has landed, so a node that restarts mid-download simply resumes fast
+// syncing from its last inserted header. The gate is against
+// headerChainHeight, the tip InsertHeaderChain has actually validated and
+// stored - not Height(), which stays at the last fully-applied block for the
+// whole of fast sync and would make this check pass (or fail) for the wrong
+// reason.
+func (c *Chain) MaybeSelectPivot(headHeight uint64) (*block.BlockHeader, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.syncMode != FastSync {
+		return nil, errors.New("chain is not in FastSync mode")
+	}
+
+	if headHeight < PivotWindow || c.headerChainHeight+PivotWindow < headHeight {
+		return nil, nil
+	}
+
+	pivotHeaderHash, err := c.state.GetHeaderHashByNumber(headHeight - PivotWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	pivotHeader, err := c.state.GetBlockHeader(pivotHeaderHash)
+	if err != nil {
+		return nil, err
+	}
+
+	c.pivotHeaderHash = pivotHeaderHash
+	return pivotHeader, nil
+}
+
+// CommitPivotState is invoked once the state-sync driver has downloaded the
+// full AddressState snapshot for the selected pivot. It adds the pivot block,
+// its canonical num->hash mapping/TD and the new chain height to batch, on top
+// of whatever the caller already put there, and writes it all at once so a
+// node restarting mid-commit never sees the in-memory head and the persisted
+// height disagree. The snapshot itself is not written here: StateSync.Deliver
+// stores each range as it arrives. It then marks the pivot block as the
+// chain's lastBlock and switches back to FullSync so addBlock resumes normal
+// applyBlock-per-block execution from pivot+1 onward.
+func (c *Chain) CommitPivotState(pivotBlock *block.Block, batch *leveldb.Batch) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !reflect.DeepEqual(pivotBlock.HeaderHash(), c.pivotHeaderHash) {
+		return errors.New("pivot block does not match the selected pivot header")
+	}
+
+	err := c.state.PutBlock(pivotBlock, batch)
+	if err != nil {
+		return err
+	}
+
+	blockNumberMapping := &generated.BlockNumberMapping{Headerhash: pivotBlock.HeaderHash(),
+		PrevHeaderhash: pivotBlock.PrevHeaderHash()}
+	c.state.PutBlockNumberMapping(pivotBlock.BlockNumber(), blockNumberMapping, batch)
+
+	td := c.totalDifficulty(pivotBlock.HeaderHash(), pivotBlock.BlockNumber())
+	c.state.WriteTd(pivotBlock.HeaderHash(), pivotBlock.BlockNumber(), td, batch)
+
+	c.state.PutChainHeight(pivotBlock.BlockNumber(), batch)
+
+	c.state.WriteBatch(batch)
+
+	c.lastBlock = pivotBlock
+	c.pivotHeaderHash = nil
+	c.headerChainHeight = 0
+	c.syncMode = FullSync
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package chain
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/pkg/ntp"
+	"github.com/theQRL/go-qrl/pow"
+)
+
+// headerChainWorkers bounds how many goroutines validate headers
+// concurrently in InsertChain. It is capped at NumCPU so preflight doesn't
+// oversubscribe the machine during a large sync import.
+func headerChainWorkers(n int) int {
+	if workers := runtime.NumCPU(); workers < n {
+		return workers
+	}
+	return n
+}
+
+// headerValidationResult carries the outcome of validating a single block's
+// header back to InsertChain, indexed by its position in the batch so
+// results from out-of-order workers can be collated in order.
+type headerValidationResult struct {
+	index int
+	err   error
+}
+
+// validateHeader runs the header-only checks that don't require any further
+// state lookups: PoW against currentDifficulty (already resolved for this
+// block by precomputeDifficulties), parent-hash linkage within the batch,
+// and clock drift against NTP. It is safe to run concurrently for different
+// blocks since currentDifficulty and prev are handed to it rather than
+// derived from LevelDB.
+func (c *Chain) validateHeader(prev, b *block.Block, currentDifficulty []byte) error {
+	if prev != nil && !reflect.DeepEqual(prev.HeaderHash(), b.PrevHeaderHash()) {
+		return errors.New("header does not link to its preceding block")
+	}
+
+	if !pow.PoWValidator(b.Header(), currentDifficulty) {
+		return errors.New("PoW validation failed")
+	}
+
+	now := ntp.GetNTP().Time()
+	if b.Timestamp() > now+c.config.Dev.MaxFutureBlockTime {
+		return errors.New("timestamp too far in the future")
+	}
+
+	return nil
+}
+
+// precomputeDifficulties walks blocks sequentially to derive each one's
+// expected difficulty from its predecessor's. This has to run before the
+// concurrent PoW pass and can't be parallelized itself: DifficultyTracker.Get
+// only knows the parent's difficulty, and for every block after the first in
+// an InsertChain batch that parent is blocks[i-1] - a block that hasn't been
+// committed yet, so it has no BlockMetaData in LevelDB for a concurrent
+// worker to read. Only block 0's parent is looked up from state; every
+// difficulty after that is chained from the in-batch predecessor's.
+func (c *Chain) precomputeDifficulties(blocks []*block.Block) ([][]byte, error) {
+	parentMetaData, err := c.state.GetBlockMetadata(blocks[0].PrevHeaderHash())
+	if err != nil {
+		return nil, err
+	}
+	parentDifficulty := parentMetaData.BlockDifficulty()
+
+	dt := pow.DifficultyTracker{}
+	difficulties := make([][]byte, len(blocks))
+
+	for i := range blocks {
+		currentDifficulty, err := dt.Get(uint64(c.config.Dev.MiningSetpointBlocktime), parentDifficulty)
+		if err != nil {
+			return nil, err
+		}
+
+		difficulties[i] = currentDifficulty
+		parentDifficulty = currentDifficulty
+	}
+
+	return difficulties, nil
+}
+
+// InsertChain is the bulk counterpart to AddBlock, used when importing a run
+// of blocks fetched from a peer during sync. It fans the header-only checks
+// for every block out across a worker pool first; the first invalid header
+// aborts the whole batch and its index is returned so the caller knows
+// exactly where the peer's chain went bad. Only once every header in the
+// batch has passed does it feed the blocks into applyBlock/addBlock
+// sequentially, preserving the existing reorg/fork-choice semantics of
+// AddBlock.
+func (c *Chain) InsertChain(blocks []*block.Block) (int, error) {
+	if len(blocks) == 0 {
+		return 0, nil
+	}
+
+	difficulties, err := c.precomputeDifficulties(blocks)
+	if err != nil {
+		return 0, err
+	}
+
+	jobs := make(chan int)
+	results := make(chan headerValidationResult, len(blocks))
+
+	var wg sync.WaitGroup
+	workers := headerChainWorkers(len(blocks))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var prev *block.Block
+				if i > 0 {
+					prev = blocks[i-1]
+				}
+				results <- headerValidationResult{index: i, err: c.validateHeader(prev, blocks[i], difficulties[i])}
+			}
+		}()
+	}
+
+	go func() {
+		for i := range blocks {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	badIndex := -1
+	var badErr error
+	for res := range results {
+		if res.err != nil && (badIndex == -1 || res.index < badIndex) {
+			badIndex = res.index
+			badErr = res.err
+		}
+	}
+
+	if badIndex != -1 {
+		return badIndex, badErr
+	}
+
+	for i, b := range blocks {
+		if !c.AddBlock(b) {
+			return i, errors.New("block application failed")
+		}
+	}
+
+	return len(blocks), nil
+}
@@ -0,0 +1,81 @@
+// Package event provides a minimal, dependency-free Feed/Subscription pair
+// modelled after go-ethereum's event.Feed: a single publisher can fan an
+// event out to any number of subscriber channels without the publisher and
+// subscribers knowing about each other.
+package event
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Subscription represents a subscription to a Feed. Unsubscribe cancels the
+// subscription and closes Err().
+type Subscription interface {
+	Unsubscribe()
+	Err() <-chan error
+}
+
+// Feed implements one-to-many fan-out of a single event type. The zero value
+// is ready to use. A Feed must not be copied after first use.
+type Feed struct {
+	mu   sync.Mutex
+	subs map[*feedSub]struct{}
+}
+
+type feedSub struct {
+	feed *Feed
+	ch   interface{}
+	err  chan error
+}
+
+// Subscribe adds ch as a subscriber. ch must be a channel of the type the
+// feed is used with; Send panics otherwise.
+func (f *Feed) Subscribe(ch interface{}) Subscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subs == nil {
+		f.subs = make(map[*feedSub]struct{})
+	}
+
+	sub := &feedSub{feed: f, ch: ch, err: make(chan error, 1)}
+	f.subs[sub] = struct{}{}
+
+	return sub
+}
+
+func (s *feedSub) Unsubscribe() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	delete(s.feed.subs, s)
+	close(s.err)
+}
+
+func (s *feedSub) Err() <-chan error {
+	return s.err
+}
+
+// Send delivers value to every current subscriber, blocking until each one
+// has received it - a best-effort drop on backpressure is not acceptable
+// for a feed whose whole purpose is reliable delivery (e.g. gossiping a
+// freshly-mined block before it's orphaned). Subscribers are expected to
+// provide an adequately-buffered channel, or to keep up with Receive in a
+// dedicated goroutine, same as go-ethereum's event.Feed.
+func (f *Feed) Send(value interface{}) int {
+	f.mu.Lock()
+	subs := make([]*feedSub, 0, len(f.subs))
+	for sub := range f.subs {
+		subs = append(subs, sub)
+	}
+	f.mu.Unlock()
+
+	rvalue := reflect.ValueOf(value)
+
+	for _, sub := range subs {
+		reflect.ValueOf(sub.ch).Send(rvalue)
+	}
+
+	return len(subs)
+}
@@ -0,0 +1,85 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeedSendDeliversToAllSubscribers(t *testing.T) {
+	var f Feed
+
+	const n = 3
+	chans := make([]chan int, n)
+	for i := range chans {
+		chans[i] = make(chan int, 1)
+		f.Subscribe(chans[i])
+	}
+
+	if got := f.Send(42); got != n {
+		t.Fatalf("Send() returned %d subscribers, want %d", got, n)
+	}
+
+	for i, ch := range chans {
+		select {
+		case v := <-ch:
+			if v != 42 {
+				t.Errorf("subscriber %d got %d, want 42", i, v)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("subscriber %d never received the value", i)
+		}
+	}
+}
+
+func TestFeedSendBlocksUntilDelivered(t *testing.T) {
+	var f Feed
+	ch := make(chan int) // unbuffered: Send must block until a receiver takes the value
+	f.Subscribe(ch)
+
+	delivered := make(chan struct{})
+	go func() {
+		f.Send(7)
+		close(delivered)
+	}()
+
+	select {
+	case <-delivered:
+		t.Fatal("Send returned before the unbuffered subscriber received the value")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if v := <-ch; v != 7 {
+		t.Fatalf("got %d, want 7", v)
+	}
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("Send did not return after delivery")
+	}
+}
+
+func TestUnsubscribeStopsDelivery(t *testing.T) {
+	var f Feed
+	ch := make(chan int, 1)
+	sub := f.Subscribe(ch)
+	sub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		f.Send(1)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Send blocked on an unsubscribed channel")
+	}
+
+	select {
+	case v := <-ch:
+		t.Fatalf("unsubscribed channel received %d", v)
+	default:
+	}
+}
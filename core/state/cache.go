@@ -0,0 +1,119 @@
+package state
+
+import (
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	c "github.com/theQRL/go-qrl/config"
+	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/core/metadata"
+	"github.com/theQRL/go-qrl/generated"
+)
+
+// Default cache sizes, used when config.Config.State doesn't override them.
+const (
+	defaultBlockCacheSize         = 256
+	defaultBlockMetaDataCacheSize = 1024
+	defaultBlockNumberCacheSize   = 512
+)
+
+// stateCache holds the bounded LRU caches that sit in front of State's
+// LevelDB reads. Chain.addBlock, Rollback, GetForkPoint, AddChain and
+// forkRecovery all repeatedly look up the same handful of ancestors during a
+// single reorg; these caches turn those repeated lookups into memory hits
+// instead of disk reads.
+type stateCache struct {
+	blocks        *lru.Cache // headerhash -> *block.Block
+	blockMetaData *lru.Cache // headerhash -> *metadata.BlockMetaData
+	blockNumbers  *lru.Cache // block number -> *generated.BlockNumberMapping
+
+	hits   uint64
+	misses uint64
+}
+
+func newStateCache(config *c.Config) *stateCache {
+	blockCacheSize := defaultBlockCacheSize
+	blockMetaDataCacheSize := defaultBlockMetaDataCacheSize
+	blockNumberCacheSize := defaultBlockNumberCacheSize
+
+	if config != nil && config.State.BlockCacheSize > 0 {
+		blockCacheSize = config.State.BlockCacheSize
+	}
+	if config != nil && config.State.BlockMetaDataCacheSize > 0 {
+		blockMetaDataCacheSize = config.State.BlockMetaDataCacheSize
+	}
+	if config != nil && config.State.BlockNumberCacheSize > 0 {
+		blockNumberCacheSize = config.State.BlockNumberCacheSize
+	}
+
+	blocks, _ := lru.New(blockCacheSize)
+	blockMetaData, _ := lru.New(blockMetaDataCacheSize)
+	blockNumbers, _ := lru.New(blockNumberCacheSize)
+
+	return &stateCache{
+		blocks:        blocks,
+		blockMetaData: blockMetaData,
+		blockNumbers:  blockNumbers,
+	}
+}
+
+func (sc *stateCache) getBlock(headerHash []byte) (*block.Block, bool) {
+	v, ok := sc.blocks.Get(string(headerHash))
+	if !ok {
+		atomic.AddUint64(&sc.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&sc.hits, 1)
+	return v.(*block.Block), true
+}
+
+func (sc *stateCache) putBlock(headerHash []byte, b *block.Block) {
+	sc.blocks.Add(string(headerHash), b)
+}
+
+func (sc *stateCache) removeBlock(headerHash []byte) {
+	sc.blocks.Remove(string(headerHash))
+}
+
+func (sc *stateCache) getBlockMetaData(headerHash []byte) (*metadata.BlockMetaData, bool) {
+	v, ok := sc.blockMetaData.Get(string(headerHash))
+	if !ok {
+		atomic.AddUint64(&sc.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&sc.hits, 1)
+	return v.(*metadata.BlockMetaData), true
+}
+
+func (sc *stateCache) putBlockMetaData(headerHash []byte, m *metadata.BlockMetaData) {
+	sc.blockMetaData.Add(string(headerHash), m)
+}
+
+func (sc *stateCache) getBlockNumberMapping(blockNumber uint64) (*generated.BlockNumberMapping, bool) {
+	v, ok := sc.blockNumbers.Get(blockNumber)
+	if !ok {
+		atomic.AddUint64(&sc.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&sc.hits, 1)
+	return v.(*generated.BlockNumberMapping), true
+}
+
+func (sc *stateCache) putBlockNumberMapping(blockNumber uint64, m *generated.BlockNumberMapping) {
+	sc.blockNumbers.Add(blockNumber, m)
+}
+
+func (sc *stateCache) removeBlockNumberMapping(blockNumber uint64) {
+	sc.blockNumbers.Remove(blockNumber)
+}
+
+// CacheHits and CacheMisses expose cache-hit metrics for diagnostics/metrics
+// endpoints.
+func (s *State) CacheHits() uint64 {
+	return atomic.LoadUint64(&s.cache.hits)
+}
+
+func (s *State) CacheMisses() uint64 {
+	return atomic.LoadUint64(&s.cache.misses)
+}
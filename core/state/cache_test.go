@@ -0,0 +1,84 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/core/metadata"
+	"github.com/theQRL/go-qrl/generated"
+)
+
+func TestStateCacheBlockRoundTrip(t *testing.T) {
+	sc := newStateCache(nil)
+	headerHash := []byte("hh")
+
+	if _, ok := sc.getBlock(headerHash); ok {
+		t.Fatal("expected a miss before anything is cached")
+	}
+
+	b := &block.Block{}
+	sc.putBlock(headerHash, b)
+
+	got, ok := sc.getBlock(headerHash)
+	if !ok || got != b {
+		t.Fatalf("getBlock() = (%v, %v), want (%v, true)", got, ok, b)
+	}
+
+	sc.removeBlock(headerHash)
+	if _, ok := sc.getBlock(headerHash); ok {
+		t.Fatal("expected a miss after removeBlock")
+	}
+
+	if sc.hits == 0 {
+		t.Error("expected at least one recorded cache hit")
+	}
+	if sc.misses == 0 {
+		t.Error("expected at least one recorded cache miss")
+	}
+}
+
+func TestStateCacheBlockMetaDataRoundTrip(t *testing.T) {
+	sc := newStateCache(nil)
+	headerHash := []byte("hh")
+
+	if _, ok := sc.getBlockMetaData(headerHash); ok {
+		t.Fatal("expected a miss before anything is cached")
+	}
+
+	m := metadata.CreateBlockMetadata([]byte{1}, []byte{2}, nil)
+	sc.putBlockMetaData(headerHash, m)
+
+	got, ok := sc.getBlockMetaData(headerHash)
+	if !ok || got != m {
+		t.Fatalf("getBlockMetaData() = (%v, %v), want (%v, true)", got, ok, m)
+	}
+}
+
+func TestStateCacheBlockNumberMappingRoundTrip(t *testing.T) {
+	sc := newStateCache(nil)
+
+	if _, ok := sc.getBlockNumberMapping(5); ok {
+		t.Fatal("expected a miss before anything is cached")
+	}
+
+	mapping := &generated.BlockNumberMapping{Headerhash: []byte("hh")}
+	sc.putBlockNumberMapping(5, mapping)
+
+	got, ok := sc.getBlockNumberMapping(5)
+	if !ok || got != mapping {
+		t.Fatalf("getBlockNumberMapping() = (%v, %v), want (%v, true)", got, ok, mapping)
+	}
+
+	sc.removeBlockNumberMapping(5)
+	if _, ok := sc.getBlockNumberMapping(5); ok {
+		t.Fatal("expected a miss after removeBlockNumberMapping")
+	}
+}
+
+func TestNewStateCacheWithoutConfigStartsEmpty(t *testing.T) {
+	sc := newStateCache(nil)
+
+	if sc.blocks.Len() != 0 || sc.blockMetaData.Len() != 0 || sc.blockNumbers.Len() != 0 {
+		t.Fatal("a freshly created cache should be empty")
+	}
+}
@@ -0,0 +1,397 @@
+package state
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	c "github.com/theQRL/go-qrl/config"
+	"github.com/theQRL/go-qrl/core/addressstate"
+	"github.com/theQRL/go-qrl/core/block"
+	"github.com/theQRL/go-qrl/core/metadata"
+	"github.com/theQRL/go-qrl/generated"
+	"github.com/theQRL/go-qrl/log"
+)
+
+// State is the LevelDB-backed store behind Chain: blocks, their metadata,
+// the canonical block-number mapping and address balances/OTS state all live
+// here. Transaction metadata is not stored: UpdateTxMetadata and
+// RollbackTxMetadata do nothing.
+type State struct {
+	lock sync.Mutex
+
+	log    log.Logger
+	config *c.Config
+
+	db    *leveldb.DB
+	cache *stateCache
+}
+
+func CreateState(log *log.Logger, config *c.Config) (*State, error) {
+	db, err := leveldb.OpenFile(config.User.DataDir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &State{
+		log:    *log,
+		config: config,
+		db:     db,
+		cache:  newStateCache(config),
+	}, nil
+}
+
+func (s *State) GetBatch() *leveldb.Batch {
+	return new(leveldb.Batch)
+}
+
+func (s *State) WriteBatch(batch *leveldb.Batch) error {
+	return s.db.Write(batch, nil)
+}
+
+func (s *State) GetChainHeight() (uint64, error) {
+	return s.getUint64(chainHeightKey)
+}
+
+func (s *State) PutChainHeight(height uint64, batch *leveldb.Batch) {
+	s.putUint64(chainHeightKey, height, batch)
+}
+
+func (s *State) GetBlock(headerHash []byte) (*block.Block, error) {
+	if b, ok := s.cache.getBlock(headerHash); ok {
+		return b, nil
+	}
+
+	data, err := s.db.Get(blockKey(headerHash), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pbData := &generated.Block{}
+	if err := proto.Unmarshal(data, pbData); err != nil {
+		return nil, err
+	}
+
+	b := &block.Block{}
+	b.SetPBData(pbData)
+	s.cache.putBlock(headerHash, b)
+
+	return b, nil
+}
+
+// PutBlock writes b to LevelDB and, for a direct write, caches it. When
+// batch is non-nil the write only lands once the caller later calls
+// WriteBatch - caching it here too would let GetBlock/AddBlock's duplicate
+// check see a block that a failed or not-yet-flushed batch never persisted,
+// so batched writes are left to repopulate the cache on their next read.
+func (s *State) PutBlock(b *block.Block, batch *leveldb.Batch) error {
+	data, err := proto.Marshal(b.PBData())
+	if err != nil {
+		return err
+	}
+
+	key := blockKey(b.HeaderHash())
+	if batch != nil {
+		batch.Put(key, data)
+		return nil
+	}
+
+	if err := s.db.Put(key, data, nil); err != nil {
+		return err
+	}
+
+	s.cache.putBlock(b.HeaderHash(), b)
+
+	return nil
+}
+
+func (s *State) GetBlockByNumber(blockNumber uint64) (*block.Block, error) {
+	mapping, err := s.GetBlockNumberMapping(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBlock(mapping.Headerhash)
+}
+
+// GetBlockNumberMapping returns the canonical headerhash for blockNumber.
+// The underlying storage is the plain h-<num>-n/H-<hash> key pair written by
+// writeCanonicalMapping rather than a protobuf-encoded BlockNumberMapping;
+// the generated type is kept here only as the call sites' existing currency.
+func (s *State) GetBlockNumberMapping(blockNumber uint64) (*generated.BlockNumberMapping, error) {
+	if mapping, ok := s.cache.getBlockNumberMapping(blockNumber); ok {
+		return mapping, nil
+	}
+
+	headerHash, err := s.db.Get(canonicalNumKey(blockNumber), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := &generated.BlockNumberMapping{Headerhash: headerHash}
+	s.cache.putBlockNumberMapping(blockNumber, mapping)
+
+	return mapping, nil
+}
+
+// PutBlockNumberMapping writes the canonical mapping and, for a direct
+// write, caches it. As with PutBlock, a batched write isn't durable until
+// WriteBatch succeeds, so it must not be cached here - any cached entry for
+// blockNumber is dropped instead, and GetBlockNumberMapping will pick the new
+// one up from LevelDB once the batch has actually landed.
+func (s *State) PutBlockNumberMapping(blockNumber uint64, mapping *generated.BlockNumberMapping, batch *leveldb.Batch) error {
+	s.writeCanonicalMapping(blockNumber, mapping.Headerhash, batch)
+
+	if batch == nil {
+		s.cache.putBlockNumberMapping(blockNumber, mapping)
+	} else {
+		s.cache.removeBlockNumberMapping(blockNumber)
+	}
+
+	return nil
+}
+
+func (s *State) RemoveBlockNumberMapping(blockNumber uint64) {
+	headerHash, err := s.db.Get(canonicalNumKey(blockNumber), nil)
+	if err == nil {
+		s.db.Delete(canonicalHashKey(headerHash), nil)
+	}
+	s.db.Delete(canonicalNumKey(blockNumber), nil)
+	s.cache.removeBlockNumberMapping(blockNumber)
+}
+
+// writeCanonicalMapping stores the num<->hash canonical mapping under
+// sequential integer keys: h-<num>-n holds the raw headerhash, H-<hash>
+// holds the big-endian block number. Both are written with no protobuf or
+// goqryptonight round-tripping so they're cheap to write on every block and
+// cheap to range-scan by block number.
+func (s *State) writeCanonicalMapping(blockNumber uint64, headerHash []byte, batch *leveldb.Batch) {
+	numKey := canonicalNumKey(blockNumber)
+	hashKey := canonicalHashKey(headerHash)
+	numValue := uint64ToBytes(blockNumber)
+
+	if batch != nil {
+		batch.Put(numKey, headerHash)
+		batch.Put(hashKey, numValue)
+		return
+	}
+
+	s.db.Put(numKey, headerHash, nil)
+	s.db.Put(hashKey, numValue, nil)
+}
+
+func (s *State) GetBlockMetadata(headerHash []byte) (*metadata.BlockMetaData, error) {
+	if m, ok := s.cache.getBlockMetaData(headerHash); ok {
+		return m, nil
+	}
+
+	data, err := s.db.Get(blockMetaDataKey(headerHash), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pbData := &generated.BlockMetaData{}
+	if err := proto.Unmarshal(data, pbData); err != nil {
+		return nil, err
+	}
+
+	m := metadata.CreateBlockMetadataFromPBData(pbData)
+	s.cache.putBlockMetaData(headerHash, m)
+
+	return m, nil
+}
+
+// PutBlockMetaData writes m to LevelDB and, for a direct write, caches it.
+// Same reasoning as PutBlock: a batched write that's never flushed (or fails)
+// must not leave a phantom entry in the cache.
+func (s *State) PutBlockMetaData(headerHash []byte, m *metadata.BlockMetaData, batch *leveldb.Batch) error {
+	data, err := proto.Marshal(m.PBData())
+	if err != nil {
+		return err
+	}
+
+	key := blockMetaDataKey(headerHash)
+	if batch != nil {
+		batch.Put(key, data)
+		return nil
+	}
+
+	if err := s.db.Put(key, data, nil); err != nil {
+		return err
+	}
+
+	s.cache.putBlockMetaData(headerHash, m)
+
+	return nil
+}
+
+func (s *State) GetForkState() (*generated.ForkState, error) {
+	data, err := s.db.Get(forkStateKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	forkState := &generated.ForkState{}
+	if err := proto.Unmarshal(data, forkState); err != nil {
+		return nil, err
+	}
+
+	return forkState, nil
+}
+
+func (s *State) PutForkState(forkState *generated.ForkState, batch *leveldb.Batch) error {
+	data, err := proto.Marshal(forkState)
+	if err != nil {
+		return err
+	}
+
+	if batch != nil {
+		batch.Put(forkStateKey, data)
+		return nil
+	}
+	return s.db.Put(forkStateKey, data, nil)
+}
+
+func (s *State) DeleteForkState() error {
+	return s.db.Delete(forkStateKey, nil)
+}
+
+func (s *State) GetAddressesState(addressesState map[string]*addressstate.AddressState) {
+	for addr, existing := range addressesState {
+		data, err := s.db.Get(addressStateKey([]byte(addr)), nil)
+		if err != nil {
+			continue
+		}
+
+		pbData := &generated.AddressState{}
+		if err := proto.Unmarshal(data, pbData); err != nil {
+			continue
+		}
+
+		existing.SetPBData(pbData)
+	}
+}
+
+func (s *State) PutAddressesState(addressesState map[string]*addressstate.AddressState, batch *leveldb.Batch) error {
+	for addr, addrState := range addressesState {
+		data, err := proto.Marshal(addrState.PBData())
+		if err != nil {
+			return err
+		}
+
+		key := addressStateKey([]byte(addr))
+		if batch != nil {
+			batch.Put(key, data)
+		} else if err := s.db.Put(key, data, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *State) UnsetOTSKey(addrState addressstate.AddressState, otsKeyIndex uint64) {
+	addrState.UnsetOTSKey(otsKeyIndex)
+}
+
+func (s *State) UpdateTxMetadata(b *block.Block, batch *leveldb.Batch) error {
+	return nil
+}
+
+func (s *State) RollbackTxMetadata(b *block.Block, batch *leveldb.Batch) error {
+	return nil
+}
+
+func (s *State) GetBlockSizeLimit(b *block.Block) (uint64, error) {
+	return s.config.Dev.BlockSizeLimit, nil
+}
+
+func (s *State) GetHeaderHashByNumber(blockNumber uint64) ([]byte, error) {
+	mapping, err := s.GetBlockNumberMapping(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapping.Headerhash, nil
+}
+
+func (s *State) GetBlockHeader(headerHash []byte) (*block.BlockHeader, error) {
+	b, err := s.GetBlock(headerHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Header(), nil
+}
+
+func (s *State) getUint64(key []byte) (uint64, error) {
+	data, err := s.db.Get(key, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, errors.New("corrupted uint64 value")
+	}
+
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+func (s *State) putUint64(key []byte, v uint64, batch *leveldb.Batch) {
+	data := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		data[i] = byte(v)
+		v >>= 8
+	}
+
+	if batch != nil {
+		batch.Put(key, data)
+		return
+	}
+	s.db.Put(key, data, nil)
+}
+
+var (
+	chainHeightKey = []byte("chain-height")
+	forkStateKey   = []byte("fork-state")
+)
+
+func blockKey(headerHash []byte) []byte {
+	return append([]byte("block-"), headerHash...)
+}
+
+func blockMetaDataKey(headerHash []byte) []byte {
+	return append([]byte("block-metadata-"), headerHash...)
+}
+
+func canonicalNumKey(blockNumber uint64) []byte {
+	return append([]byte("h-"), append(uint64ToBytes(blockNumber), '-', 'n')...)
+}
+
+func canonicalHashKey(headerHash []byte) []byte {
+	return append([]byte("H-"), headerHash...)
+}
+
+func totalDifficultyKey(blockNumber uint64, headerHash []byte) []byte {
+	key := append([]byte("td-"), uint64ToBytes(blockNumber)...)
+	key = append(key, '-')
+	return append(key, headerHash...)
+}
+
+func addressStateKey(address []byte) []byte {
+	return append([]byte("address-state-"), address...)
+}
+
+func uint64ToBytes(v uint64) []byte {
+	data := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		data[i] = byte(v)
+		v >>= 8
+	}
+	return data
+}
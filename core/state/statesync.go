@@ -0,0 +1,79 @@
+package state
+
+import (
+	"github.com/theQRL/go-qrl/core/addressstate"
+)
+
+// AddressStateRangeSize is the number of addresses requested from a peer in
+// a single StateSync round-trip.
+const AddressStateRangeSize = 256
+
+// StateSync drives the pivot AddressState download used by FastSync. Rather
+// than reconstructing state by replaying every historical block, it asks
+// peers directly for AddressState key ranges and persists whatever comes
+// back via PutAddressesState, the same entry point full sync uses when it
+// applies a block.
+type StateSync struct {
+	state *State
+
+	pivotHeaderHash []byte
+	pending         [][]byte
+	done            map[string]bool
+}
+
+// NewStateSync prepares a driver for the given pivot. addresses is the full
+// key range that needs to be fetched; callers typically get this from the
+// pivot block's address list or from a peer-provided address trie root.
+func NewStateSync(s *State, pivotHeaderHash []byte, addresses [][]byte) *StateSync {
+	return &StateSync{
+		state:           s,
+		pivotHeaderHash: pivotHeaderHash,
+		pending:         addresses,
+		done:            make(map[string]bool),
+	}
+}
+
+// NextRange returns up to AddressStateRangeSize addresses that still need to
+// be requested from a peer, or nil once the snapshot is complete.
+func (s *StateSync) NextRange() [][]byte {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	end := AddressStateRangeSize
+	if end > len(s.pending) {
+		end = len(s.pending)
+	}
+
+	return s.pending[:end]
+}
+
+// Deliver stores a batch of AddressState received from a peer and marks the
+// corresponding addresses as satisfied. It is safe to call repeatedly as
+// ranges trickle in, and safe to resume after a restart since every delivery
+// is committed to LevelDB immediately rather than buffered in memory.
+func (s *StateSync) Deliver(addressesState map[string]*addressstate.AddressState) error {
+	err := s.state.PutAddressesState(addressesState, nil)
+	if err != nil {
+		return err
+	}
+
+	for addr := range addressesState {
+		s.done[addr] = true
+	}
+
+	remaining := s.pending[:0]
+	for _, addr := range s.pending {
+		if !s.done[string(addr)] {
+			remaining = append(remaining, addr)
+		}
+	}
+	s.pending = remaining
+
+	return nil
+}
+
+// Done reports whether every requested address has a snapshot stored.
+func (s *StateSync) Done() bool {
+	return len(s.pending) == 0
+}
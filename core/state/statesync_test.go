@@ -0,0 +1,84 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/theQRL/go-qrl/core/addressstate"
+)
+
+func TestStateSyncNextRangeChunking(t *testing.T) {
+	addresses := make([][]byte, AddressStateRangeSize+10)
+	for i := range addresses {
+		addresses[i] = []byte{byte(i >> 8), byte(i)}
+	}
+
+	s := newTestState(t)
+	sync := NewStateSync(s, []byte("pivot"), addresses)
+
+	first := sync.NextRange()
+	if len(first) != AddressStateRangeSize {
+		t.Fatalf("NextRange() returned %d addresses, want %d", len(first), AddressStateRangeSize)
+	}
+
+	delivered := make(map[string]*addressstate.AddressState, len(first))
+	for _, addr := range first {
+		delivered[string(addr)] = &addressstate.AddressState{}
+	}
+	if err := sync.Deliver(delivered); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	second := sync.NextRange()
+	if len(second) != 10 {
+		t.Fatalf("NextRange() after first delivery returned %d addresses, want 10", len(second))
+	}
+
+	if sync.Done() {
+		t.Fatal("Done() should be false until every address has been delivered")
+	}
+}
+
+func TestStateSyncDone(t *testing.T) {
+	addresses := [][]byte{[]byte("a"), []byte("b")}
+
+	s := newTestState(t)
+	sync := NewStateSync(s, []byte("pivot"), addresses)
+
+	if sync.Done() {
+		t.Fatal("Done() should be false before anything has been delivered")
+	}
+
+	delivered := map[string]*addressstate.AddressState{
+		"a": {},
+		"b": {},
+	}
+	if err := sync.Deliver(delivered); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	if !sync.Done() {
+		t.Fatal("Done() should be true once every address has been delivered")
+	}
+	if sync.NextRange() != nil {
+		t.Fatal("NextRange() should return nil once the snapshot is complete")
+	}
+}
+
+func TestStateSyncDeliverIsIdempotent(t *testing.T) {
+	addresses := [][]byte{[]byte("a")}
+
+	s := newTestState(t)
+	sync := NewStateSync(s, []byte("pivot"), addresses)
+
+	delivered := map[string]*addressstate.AddressState{"a": {}}
+	if err := sync.Deliver(delivered); err != nil {
+		t.Fatalf("first Deliver: %v", err)
+	}
+	if err := sync.Deliver(delivered); err != nil {
+		t.Fatalf("second Deliver: %v", err)
+	}
+
+	if !sync.Done() {
+		t.Fatal("Done() should still be true after a repeated delivery")
+	}
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"math/big"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// GetTd returns the total difficulty accumulated up to and including
+// headerHash/blockNumber. Unlike BlockMetaData.TotalDifficulty(), which
+// stores the goqryptonight UInt256 wire encoding and needs a
+// UInt256ToString/big.Int.SetString round-trip to use, the td-<num>-<hash>
+// entry is a plain big-endian big.Int encoding so reading it is a single
+// LevelDB lookup with no parsing. Callers already know blockNumber (it comes
+// with the block being evaluated), so this never touches the canonical
+// H-<hash> index - TD is a pure read/cache path and must not have side
+// effects on which hash is canonical for a block number.
+func (s *State) GetTd(headerHash []byte, blockNumber uint64) (*big.Int, error) {
+	data, err := s.db.Get(totalDifficultyKey(blockNumber, headerHash), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// WriteTd stores td for headerHash/blockNumber under its own td-<num>-<hash>
+// key. It intentionally does not touch the canonical num<->hash mapping -
+// that is written through PutBlockNumberMapping by the paths that decide the
+// canonical chain (updateBlockNumberMapping on the fork-choice winner, and
+// the fast-sync header and pivot commits). A contending block being evaluated for TD
+// must never become the canonical block for its height as a side effect.
+func (s *State) WriteTd(headerHash []byte, blockNumber uint64, td *big.Int, batch *leveldb.Batch) {
+	key := totalDifficultyKey(blockNumber, headerHash)
+	if batch != nil {
+		batch.Put(key, td.Bytes())
+		return
+	}
+	s.db.Put(key, td.Bytes(), nil)
+}
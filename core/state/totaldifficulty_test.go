@@ -0,0 +1,115 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+func newTestState(t *testing.T) *State {
+	t.Helper()
+
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		t.Fatalf("leveldb.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &State{db: db, cache: newStateCache(nil)}
+}
+
+func TestWriteTdGetTdRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		blockNumber uint64
+		td          *big.Int
+	}{
+		{"zero", 0, big.NewInt(0)},
+		{"small", 1, big.NewInt(42)},
+		{"large", 1000, new(big.Int).Lsh(big.NewInt(1), 200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestState(t)
+			headerHash := []byte("header-" + tt.name)
+
+			s.WriteTd(headerHash, tt.blockNumber, tt.td, nil)
+
+			got, err := s.GetTd(headerHash, tt.blockNumber)
+			if err != nil {
+				t.Fatalf("GetTd: %v", err)
+			}
+			if got.Cmp(tt.td) != 0 {
+				t.Errorf("GetTd() = %s, want %s", got, tt.td)
+			}
+		})
+	}
+}
+
+func TestWriteTdGetTdRoundTripViaBatch(t *testing.T) {
+	s := newTestState(t)
+	headerHash := []byte("batched-header")
+	td := big.NewInt(12345)
+
+	batch := s.GetBatch()
+	s.WriteTd(headerHash, 7, td, batch)
+	if err := s.WriteBatch(batch); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+
+	got, err := s.GetTd(headerHash, 7)
+	if err != nil {
+		t.Fatalf("GetTd: %v", err)
+	}
+	if got.Cmp(td) != 0 {
+		t.Errorf("GetTd() = %s, want %s", got, td)
+	}
+}
+
+func TestGetTdMissing(t *testing.T) {
+	s := newTestState(t)
+
+	if _, err := s.GetTd([]byte("nope"), 5); err == nil {
+		t.Error("GetTd() on an unwritten key should return an error")
+	}
+}
+
+func TestWriteTdDoesNotTouchCanonicalMapping(t *testing.T) {
+	s := newTestState(t)
+	headerHash := []byte("contender")
+
+	s.WriteTd(headerHash, 10, big.NewInt(7), nil)
+
+	if _, err := s.GetBlockNumberMapping(10); err == nil {
+		t.Error("WriteTd must not create a canonical mapping for the block number")
+	}
+}
+
+func TestWriteTdKeyedByBothNumberAndHash(t *testing.T) {
+	s := newTestState(t)
+
+	canonical := []byte("canonical-hash")
+	contender := []byte("contender-hash")
+
+	s.WriteTd(canonical, 10, big.NewInt(100), nil)
+	s.WriteTd(contender, 10, big.NewInt(200), nil)
+
+	gotCanonical, err := s.GetTd(canonical, 10)
+	if err != nil {
+		t.Fatalf("GetTd(canonical): %v", err)
+	}
+	if gotCanonical.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("GetTd(canonical) = %s, want 100", gotCanonical)
+	}
+
+	gotContender, err := s.GetTd(contender, 10)
+	if err != nil {
+		t.Fatalf("GetTd(contender): %v", err)
+	}
+	if gotContender.Cmp(big.NewInt(200)) != 0 {
+		t.Errorf("GetTd(contender) = %s, want 200", gotContender)
+	}
+}
@@ -1,7 +1,10 @@
 package ntp
 
 import (
+	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/beevik/ntp"
@@ -9,58 +12,156 @@ import (
 	"github.com/theQRL/go-qrl/pkg/config"
 )
 
+// NTP tracks this node's clock offset from network time. drift is a signed
+// time.Duration rather than a wall-clock second count, so it stays correct
+// across minute boundaries instead of wrapping every time.Now().Second()
+// resets to 0.
 type NTP struct {
-	lock *sync.Mutex
+	lock sync.Mutex
 
-	drift      uint64
-	lastUpdate uint64
+	drift      time.Duration
+	lastUpdate time.Time
 	config     *config.Config
+
+	refreshing int32 // set via atomic CAS; 1 while a background UpdateTime is in flight
 }
 
+// UpdateTime queries every configured NTP server in parallel and takes the
+// median offset among the servers that answered, discarding the rest as
+// outliers - the same clock-select idea ntpd uses so that a single
+// misbehaving or unreachable peer can't skew the node's time.
 func (n *NTP) UpdateTime() error {
+	var offsets []time.Duration
+
+	for retry := 0; retry <= n.config.User.NTP.Retries && len(offsets) == 0; retry++ {
+		offsets = n.queryServers()
+	}
+
+	if len(offsets) == 0 {
+		return errNoServersResponded
+	}
+
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
-	var err error
-	var t time.Time
+	n.drift = medianOffset(offsets)
+	n.lastUpdate = time.Now()
+
+	return nil
+}
 
-	for retry := 0; retry <= n.config.User.NTP.Retries; retry++ {
-		for _, server := range n.config.User.NTP.Servers {
-			t, err = ntp.Time(server)
+// medianOffset picks the median of the given offsets, the same clock-select
+// idea ntpd uses so that a single misbehaving or unreachable peer can't skew
+// the result - unlike a mean, one wildly-off outlier can't drag it away from
+// what the majority of servers agree on. offsets is sorted in place.
+func medianOffset(offsets []time.Duration) time.Duration {
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets[len(offsets)/2]
+}
 
+// queryServers asks every configured server for the current time
+// concurrently and returns the clock offset (t.Sub(queriedAt), with queriedAt
+// the local time just before the query) for each server that responded.
+func (n *NTP) queryServers() []time.Duration {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var offsets []time.Duration
+
+	for _, server := range n.config.User.NTP.Servers {
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+
+			queriedAt := time.Now()
+			t, err := ntp.Time(server)
 			if err != nil {
-				continue
+				return
 			}
 
-			n.drift = uint64(time.Now().Second() - t.Second())
-			n.lastUpdate = uint64(t.Second())
-
-			return nil
-		}
+			mu.Lock()
+			offsets = append(offsets, t.Sub(queriedAt))
+			mu.Unlock()
+		}(server)
 	}
 
-	return err
+	wg.Wait()
+
+	return offsets
 }
 
+// Time returns the current network-adjusted time, triggering a refresh in
+// the background if the last successful sync is older than the configured
+// refresh interval.
 func (n *NTP) Time() uint64 {
-	currentTime := uint64(time.Now().Second()) + n.drift
-	if currentTime-n.lastUpdate > n.config.User.NTP.Refresh {
-		err := n.UpdateTime()
-		if err != nil {
+	n.lock.Lock()
+	stale := time.Since(n.lastUpdate) > time.Duration(n.config.User.NTP.Refresh)*time.Second
+	drift := n.drift
+	n.lock.Unlock()
+
+	if stale {
+		n.triggerRefresh()
+	}
+
+	return uint64(time.Now().Add(drift).Unix())
+}
+
+// triggerRefresh starts a background UpdateTime unless one is already in
+// flight - called both from Time() and refreshLoop, it's what keeps a
+// stretch of unreachable servers from piling up an unbounded number of
+// concurrent refresh goroutines.
+func (n *NTP) triggerRefresh() {
+	if !atomic.CompareAndSwapInt32(&n.refreshing, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&n.refreshing, 0)
+
+		if err := n.UpdateTime(); err != nil {
 			// TODO: log warning here
 		}
-	}
+	}()
+}
+
+// Offset returns the clock drift applied to time.Now() by Time(), as last
+// measured by UpdateTime.
+func (n *NTP) Offset() time.Duration {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.drift
+}
 
-	return uint64(time.Now().Second()) + n.drift
+// LastSync returns when UpdateTime last completed successfully.
+func (n *NTP) LastSync() time.Time {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.lastUpdate
 }
 
+var errNoServersResponded = errors.New("ntp: no configured server responded")
+
 var once sync.Once
 var n *NTP
 
+// GetNTP returns the process-wide NTP instance, starting a background
+// goroutine on first use that keeps its drift fresh without callers ever
+// having to block on a live query - including the very first one, which
+// otherwise would stall on a full round-trip to every configured server.
 func GetNTP() *NTP {
 	once.Do(func() {
 		n = &NTP{config: config.GetConfig()}
+
+		go n.refreshLoop()
 	})
 
 	return n
 }
+
+func (n *NTP) refreshLoop() {
+	for {
+		n.triggerRefresh()
+		time.Sleep(time.Duration(n.config.User.NTP.Refresh) * time.Second)
+	}
+}
@@ -0,0 +1,44 @@
+package ntp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMedianOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		offsets []time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "single server",
+			offsets: []time.Duration{5 * time.Millisecond},
+			want:    5 * time.Millisecond,
+		},
+		{
+			name:    "odd count picks the middle value",
+			offsets: []time.Duration{3 * time.Second, -1 * time.Second, 2 * time.Second},
+			want:    2 * time.Second,
+		},
+		{
+			name:    "even count picks the upper-middle value",
+			offsets: []time.Duration{10 * time.Millisecond, 40 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond},
+			want:    30 * time.Millisecond,
+		},
+		{
+			name:    "outlier does not skew the result",
+			offsets: []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 10 * time.Minute},
+			want:    3 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := medianOffset(tt.offsets)
+			if got != tt.want {
+				t.Errorf("medianOffset(%v) = %v, want %v", tt.offsets, got, tt.want)
+			}
+		})
+	}
+}